@@ -23,6 +23,24 @@ type Acl struct {
 	Managers  []string `json:"managers"`
 	Drivers   []string `json:"drivers"`
 	FleetName string   `json:"fleetName,omitempty"`
+
+	// Policies, Roles and Entries back the Authorize method. They are
+	// optional: an Acl with none of them set still authorizes through the
+	// manager/driver policies built in for the legacy Managers/Drivers
+	// lists.
+	Policies []Policy `json:"policies,omitempty"`
+	Roles    []Role   `json:"roles,omitempty"`
+	Entries  []Entry  `json:"entries,omitempty"`
+
+	// ResetCounter is bumped on every Store so a previously issued
+	// bootstrap reset file, which pins a content hash including this
+	// counter, cannot be replayed once the ACL has moved on. See
+	// AclResetFromDevice.
+	ResetCounter uint64 `json:"resetCounter,omitempty"`
+
+	// revocations is the revocation list loaded from revocations.data, if
+	// any. It is not part of the persisted ACL; see loadRevocations.
+	revocations *RevocationList `json:"-"`
 }
 
 func NewAclFromFile(sourceFolder string) (*Acl, error) {
@@ -70,30 +88,63 @@ func AclExistOnDevice(sourceFolder string) bool {
 	return true
 }
 
-func AclClearFromDevice(aclFolder string, signatureB58 string) error {
+// AclClearFromDevice removes the ACL on aclFolder. It accepts two
+// alternative ways to authorize the clear: a direct signatureB58 from a
+// current manager over clearMessageToSign, or a BearerClearAuthorization
+// carrying a manager-issued BearerToken with the CapabilityAclClear
+// capability. If neither is given and the ACL requires authorization, it
+// returns a *SignatureRequiredError. Whichever path authorizes the clear,
+// auditSignatureB58 must be that same manager's signature authorizing the
+// resulting "clear" audit log entry; see NextAuditMessageToSign.
+func AclClearFromDevice(aclFolder string, signatureB58 string, auditSignatureB58 string, bearer *BearerClearAuthorization) error {
 	if AclExistOnDevice(aclFolder) {
 		acl, err := NewAclFromFile(aclFolder)
 		if err != nil {
 			return fmt.Errorf("unable to read acl: %w", err)
 		}
 
-		if acl.Version != "" && signatureB58 == "" {
-			return ErrSignatureRequired
+		acl.loadRevocations(aclFolder)
+
+		if acl.Version != "" && signatureB58 == "" && bearer == nil {
+			hash, hashErr := acl.contentHash()
+			if hashErr != nil {
+				return ErrSignatureRequired
+			}
+			return &SignatureRequiredError{ExpectedHash: hash}
 		}
 
-		if signatureB58 != "" {
+		var actor string
+		switch {
+		case signatureB58 != "":
 			signature, err := solana.NewSignatureFromBase58(signatureB58)
 			if err != nil {
 				return fmt.Errorf("unable to decode signature: %w", err)
 			}
-			if !acl.ValidateClearSignature(signature) {
+			matchedActor, ok := acl.matchingClearManager(signature)
+			if !ok {
 				return fmt.Errorf("invalid signature")
 			}
+			actor = matchedActor
+		case bearer != nil:
+			if err := acl.ValidateBearer(bearer.Token, bearer.Presenter, bearer.PresenterSig, CapabilityAclClear, bearer.RequestPayload); err != nil {
+				return fmt.Errorf("bearer token did not authorize clear: %w", err)
+			}
+			actor = bearer.Token.Issuer.String()
 		}
 
 		if err := aclClearFromDevice(aclFolder); err != nil {
 			return fmt.Errorf("unable to clear acl: %w", err)
 		}
+
+		if actor != "" {
+			auditSignature, err := solana.NewSignatureFromBase58(auditSignatureB58)
+			if err != nil {
+				return fmt.Errorf("unable to decode audit signature: %w", err)
+			}
+			if err := appendSignedAuditEntry(aclFolder, AuditOperationClear, actor, emptyAclHash, nil, auditSignature); err != nil {
+				return fmt.Errorf("appending audit entry: %w", err)
+			}
+		}
 	}
 	return nil
 }
@@ -120,11 +171,19 @@ func aclClearFromDevice(sourceFolder string) error {
 	return nil
 }
 
-func (a *Acl) Store(destinationFolder string, signature solana.Signature) error {
-	if !a.ValidateStoreSignature(signature) {
+// Store validates signature against a, writes it to destinationFolder, and
+// appends a "store" entry to the audit log there, signed by the same
+// manager via auditSignature.
+func (a *Acl) Store(destinationFolder string, signature solana.Signature, auditSignature solana.Signature) error {
+	a.loadRevocations(destinationFolder)
+
+	actor, ok := a.matchingStoreManager(signature)
+	if !ok {
 		return fmt.Errorf("invalid signature")
 	}
 
+	a.ResetCounter++
+
 	data, err := json.Marshal(a)
 	if err != nil {
 		return fmt.Errorf("marshalling acl: %s", err)
@@ -158,6 +217,15 @@ func (a *Acl) Store(destinationFolder string, signature solana.Signature) error
 		return fmt.Errorf("writing file %w", werr)
 	}
 
+	newAclHash, err := a.contentHash()
+	if err != nil {
+		return fmt.Errorf("computing acl content hash: %w", err)
+	}
+
+	if err := appendSignedAuditEntry(destinationFolder, AuditOperationStore, actor, newAclHash, data, auditSignature); err != nil {
+		return fmt.Errorf("appending audit entry: %w", err)
+	}
+
 	return nil
 }
 
@@ -183,14 +251,30 @@ func (a *Acl) legacyStoreMessageToSign() ([]byte, error) {
 	return data, nil
 }
 
-func (a *Acl) storeMessageToSign() ([]byte, error) {
+// contentHash returns the hex MD5 of a's canonical, deterministically
+// ordered content: the fleet name, the legacy manager/driver lists, the
+// policy/role/entry tables, and ResetCounter. It is embedded in storeMessageToSign so a
+// manager signature binds the whole authorization model, and it is what an
+// operator writes to BootstrapResetFileName to prove physical access during
+// AclResetFromDevice.
+func (a *Acl) contentHash() (string, error) {
+	policies, roles, entries := sortedPolicyTables(a)
+
 	hashableAcl := struct {
-		FleetName string   `json:"fleetName,omitempty"`
-		Managers  []string `json:"managers"`
-		Drivers   []string `json:"drivers"`
+		FleetName    string   `json:"fleetName,omitempty"`
+		Managers     []string `json:"managers"`
+		Drivers      []string `json:"drivers"`
+		Policies     []Policy `json:"policies,omitempty"`
+		Roles        []Role   `json:"roles,omitempty"`
+		Entries      []Entry  `json:"entries,omitempty"`
+		ResetCounter uint64   `json:"resetCounter,omitempty"`
 	}{
-		Managers: a.Managers,
-		Drivers:  a.Drivers,
+		Managers:     a.Managers,
+		Drivers:      a.Drivers,
+		Policies:     policies,
+		Roles:        roles,
+		Entries:      entries,
+		ResetCounter: a.ResetCounter,
 	}
 
 	if a.FleetName != "" {
@@ -199,15 +283,21 @@ func (a *Acl) storeMessageToSign() ([]byte, error) {
 
 	data, err := json.Marshal(hashableAcl)
 	if err != nil {
-		return nil, fmt.Errorf("marshalling acl: %s", err)
+		return "", fmt.Errorf("marshalling acl: %s", err)
 	}
 
 	h := md5.New()
-	s := string(data)
-	io.WriteString(h, s)
+	io.WriteString(h, string(data))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (a *Acl) storeMessageToSign() ([]byte, error) {
+	hexHash, err := a.contentHash()
+	if err != nil {
+		return nil, err
+	}
 
-	hash := h.Sum(nil)
-	hexHash := hex.EncodeToString(hash)
 	message := fmt.Sprintf("Access Control List with %d manager(s) and %d driver(s). Hash: %s", len(a.Managers), len(a.Drivers), hexHash)
 
 	return []byte(message), nil
@@ -240,14 +330,56 @@ func (a *Acl) ValidateClearSignature(signature solana.Signature) bool {
 }
 
 func (a *Acl) validateSignature(data []byte, signature solana.Signature) bool {
+	_, ok := a.matchingManager(data, signature)
+	return ok
+}
+
+// matchingManager returns the first non-revoked manager whose key verifies
+// signature over data, so callers that need to know which manager acted
+// (e.g. to attribute an audit log entry) don't have to re-walk Managers
+// themselves.
+func (a *Acl) matchingManager(data []byte, signature solana.Signature) (string, bool) {
 	for _, managerAddress := range a.Managers {
 		pubKey, err := solana.PublicKeyFromBase58(managerAddress)
 		if err != nil {
-			return false
+			return "", false
+		}
+		if a.IsRevoked(pubKey) {
+			continue
 		}
 		if signature.Verify(pubKey, data) {
-			return true
+			return managerAddress, true
+		}
+	}
+	return "", false
+}
+
+// matchingStoreManager is like matchingManager but checks signature against
+// both the current and legacy store messages, mirroring
+// ValidateStoreSignature.
+func (a *Acl) matchingStoreManager(signature solana.Signature) (string, bool) {
+	data, err := a.storeMessageToSign()
+	if err == nil {
+		if manager, ok := a.matchingManager(data, signature); ok {
+			return manager, true
 		}
 	}
-	return false
+
+	data, err = a.legacyStoreMessageToSign()
+	if err != nil {
+		return "", false
+	}
+
+	return a.matchingManager(data, signature)
+}
+
+// matchingClearManager is like matchingManager but checks signature against
+// the clear message, mirroring ValidateClearSignature.
+func (a *Acl) matchingClearManager(signature solana.Signature) (string, bool) {
+	data, err := a.clearMessageToSign()
+	if err != nil {
+		return "", false
+	}
+
+	return a.matchingManager(data, signature)
 }
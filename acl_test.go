@@ -0,0 +1,100 @@
+package hivemapper_hdc_acl
+
+import (
+	"testing"
+
+	"github.com/streamingfast/solana-go"
+)
+
+// testManager bundles a keypair with its base58 pubkey for tests that need
+// to sign ACL messages and list the resulting pubkey as a manager.
+type testManager struct {
+	privateKey solana.PrivateKey
+	pubKey     solana.PublicKey
+	pubKeyB58  string
+}
+
+func newTestManager(t *testing.T) testManager {
+	t.Helper()
+
+	pubKey, privateKey, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatalf("generating private key: %s", err)
+	}
+
+	return testManager{
+		privateKey: privateKey,
+		pubKey:     pubKey,
+		pubKeyB58:  pubKey.String(),
+	}
+}
+
+func (m testManager) sign(t *testing.T, data []byte) solana.Signature {
+	t.Helper()
+
+	signature, err := m.privateKey.Sign(data)
+	if err != nil {
+		t.Fatalf("signing: %s", err)
+	}
+
+	return signature
+}
+
+// expectedPostStoreHash predicts the content hash acl will have once Store
+// bumps its ResetCounter, without mutating acl itself.
+func expectedPostStoreHash(t *testing.T, acl *Acl) string {
+	t.Helper()
+
+	clone := *acl
+	clone.ResetCounter++
+
+	hash, err := clone.contentHash()
+	if err != nil {
+		t.Fatalf("computing expected content hash: %s", err)
+	}
+
+	return hash
+}
+
+// storeAclForTest signs and stores acl in dir on manager's behalf, mutating
+// acl in place the way a real Store call does.
+func storeAclForTest(t *testing.T, dir string, acl *Acl, manager testManager) {
+	t.Helper()
+
+	storeMessage, err := acl.storeMessageToSign()
+	if err != nil {
+		t.Fatalf("building store message: %s", err)
+	}
+	storeSignature := manager.sign(t, storeMessage)
+
+	auditMessage, err := NextAuditMessageToSign(dir, AuditOperationStore, manager.pubKeyB58, expectedPostStoreHash(t, acl))
+	if err != nil {
+		t.Fatalf("building audit message: %s", err)
+	}
+	auditSignature := manager.sign(t, auditMessage)
+
+	if err := acl.Store(dir, storeSignature, auditSignature); err != nil {
+		t.Fatalf("store: %s", err)
+	}
+}
+
+// clearAclForTest signs and clears the ACL in dir on manager's behalf.
+func clearAclForTest(t *testing.T, dir string, acl *Acl, manager testManager) {
+	t.Helper()
+
+	clearMessage, err := acl.clearMessageToSign()
+	if err != nil {
+		t.Fatalf("building clear message: %s", err)
+	}
+	clearSignature := manager.sign(t, clearMessage)
+
+	auditMessage, err := NextAuditMessageToSign(dir, AuditOperationClear, manager.pubKeyB58, emptyAclHash)
+	if err != nil {
+		t.Fatalf("building audit message: %s", err)
+	}
+	auditSignature := manager.sign(t, auditMessage)
+
+	if err := AclClearFromDevice(dir, clearSignature.String(), auditSignature.String(), nil); err != nil {
+		t.Fatalf("clear: %s", err)
+	}
+}
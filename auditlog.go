@@ -0,0 +1,314 @@
+package hivemapper_hdc_acl
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/streamingfast/solana-go"
+)
+
+// AuditLogFileName is the append-only, hash-chained log of every Store and
+// Clear applied to the ACL in a folder.
+const AuditLogFileName = "acl.log"
+
+// genesisHash is the PrevHash of the first entry in an audit chain.
+const genesisHash = "00000000000000000000000000000000"
+
+// emptyAclHash stands in for NewAclHash on "clear" and "reset" entries,
+// where the operation leaves no ACL behind to hash.
+const emptyAclHash = "00000000000000000000000000000000"
+
+type AuditOperation string
+
+const (
+	AuditOperationStore AuditOperation = "store"
+	AuditOperationClear AuditOperation = "clear"
+	AuditOperationReset AuditOperation = "reset"
+)
+
+// AuditEntry is one link in the acl.log chain. AclSnapshot carries the full
+// serialized ACL for "store" entries so VerifyAuditChain can reconstruct
+// which managers were in effect at each point in the chain's history;
+// "clear" and "reset" entries, which leave no ACL behind, omit it.
+type AuditEntry struct {
+	Seq          uint64         `json:"seq"`
+	PrevHash     string         `json:"prevHash"`
+	Timestamp    time.Time      `json:"timestamp"`
+	Operation    AuditOperation `json:"operation"`
+	ActorPubKey  string         `json:"actorPubKey,omitempty"`
+	NewAclHash   string         `json:"newAclHash"`
+	AclSnapshot  []byte         `json:"aclSnapshot,omitempty"`
+	SignatureB58 string         `json:"signature,omitempty"`
+}
+
+// messageToSign is the message the entry's actor must sign to authorize it.
+func (e *AuditEntry) messageToSign() []byte {
+	return []byte(fmt.Sprintf("ACL audit entry seq=%d prev=%s op=%s hash=%s", e.Seq, e.PrevHash, e.Operation, e.NewAclHash))
+}
+
+// hash returns the hex MD5 of e's canonical JSON, which becomes the
+// PrevHash of the entry that follows it in the chain.
+func (e *AuditEntry) hash() (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("marshalling audit entry: %s", err)
+	}
+
+	h := md5.New()
+	io.WriteString(h, string(data))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadAuditLog reads every entry appended so far to folder's acl.log, in
+// order. A folder with no acl.log yet returns a nil slice, not an error.
+func ReadAuditLog(folder string) ([]AuditEntry, error) {
+	logFile, err := os.Open(path.Join(folder, AuditLogFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	defer logFile.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(logFile)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("unmarshalling audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// chainHead returns the PrevHash and Seq the next entry appended to
+// folder's acl.log must carry.
+func chainHead(folder string) (prevHash string, nextSeq uint64, err error) {
+	entries, err := ReadAuditLog(folder)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if len(entries) == 0 {
+		return genesisHash, 0, nil
+	}
+
+	head := entries[len(entries)-1]
+	headHash, err := head.hash()
+	if err != nil {
+		return "", 0, err
+	}
+
+	return headHash, head.Seq + 1, nil
+}
+
+// appendAuditEntry verifies that entry continues the chain currently on
+// disk before appending it, rejecting an entry built against a stale or
+// tampered-with head.
+func appendAuditEntry(folder string, entry AuditEntry) error {
+	expectedPrevHash, expectedSeq, err := chainHead(folder)
+	if err != nil {
+		return err
+	}
+
+	if entry.PrevHash != expectedPrevHash || entry.Seq != expectedSeq {
+		return fmt.Errorf("audit log head changed since entry was prepared: possible concurrent tampering")
+	}
+
+	data, err := json.Marshal(&entry)
+	if err != nil {
+		return fmt.Errorf("marshalling audit entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path.Join(folder, AuditLogFileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("appending audit entry: %w", err)
+	}
+
+	return f.Sync()
+}
+
+// NextAuditMessageToSign returns the message an external signer (e.g. a
+// manager's phone app, which holds the signing key but not the device's
+// local acl.log) must sign to authorize the next audit log entry for
+// folder. The returned message embeds the Seq/PrevHash of the chain
+// currently on disk, so it is only valid until another Store/Clear/Reset
+// appends to that chain: if one does between this call and the matching
+// Store/AclClearFromDevice call, appending the prepared signature fails
+// with "invalid audit signature" (see appendSignedAuditEntry) rather than
+// corrupting the chain, and the caller must call NextAuditMessageToSign
+// again and re-sign.
+func NextAuditMessageToSign(folder string, operation AuditOperation, actor string, newAclHash string) ([]byte, error) {
+	prevHash, seq, err := chainHead(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := AuditEntry{
+		Seq:         seq,
+		PrevHash:    prevHash,
+		Operation:   operation,
+		ActorPubKey: actor,
+		NewAclHash:  newAclHash,
+	}
+
+	return entry.messageToSign(), nil
+}
+
+// appendSignedAuditEntry builds the next entry in folder's chain for
+// operation, checks that signature is actor's valid signature over it, and
+// appends it. aclSnapshot should be the full serialized ACL for "store"
+// entries and nil otherwise. signature must have been produced over the
+// message NextAuditMessageToSign returns for the same (folder, operation,
+// actor, newAclHash) just before this call; if the chain advanced in the
+// meantime, verification below fails safely rather than appending a
+// mismatched entry.
+func appendSignedAuditEntry(folder string, operation AuditOperation, actor string, newAclHash string, aclSnapshot []byte, signature solana.Signature) error {
+	prevHash, seq, err := chainHead(folder)
+	if err != nil {
+		return err
+	}
+
+	entry := AuditEntry{
+		Seq:         seq,
+		PrevHash:    prevHash,
+		Timestamp:   time.Now(),
+		Operation:   operation,
+		ActorPubKey: actor,
+		NewAclHash:  newAclHash,
+		AclSnapshot: aclSnapshot,
+	}
+
+	actorKey, err := solana.PublicKeyFromBase58(actor)
+	if err != nil {
+		return fmt.Errorf("invalid actor pubkey: %w", err)
+	}
+	if !signature.Verify(actorKey, entry.messageToSign()) {
+		return fmt.Errorf("invalid audit signature (it may have been signed against a stale chain head; call NextAuditMessageToSign again and retry)")
+	}
+
+	entry.SignatureB58 = signature.String()
+
+	return appendAuditEntry(folder, entry)
+}
+
+// VerifyAuditChain walks folder's audit log from genesis, re-hashing each
+// entry to check the chain is unbroken and verifying each entry's signature
+// against the managers in effect at that point in history. A "store"
+// entry's own AclSnapshot supplies those managers going forward; a "reset"
+// entry carries no signature, since it is authorized by physical access to
+// the device rather than a manager key. The first broken link found is
+// returned.
+func VerifyAuditChain(folder string) error {
+	entries, err := ReadAuditLog(folder)
+	if err != nil {
+		return err
+	}
+
+	prevHash := genesisHash
+	var managers []string
+
+	for i, entry := range entries {
+		if entry.Seq != uint64(i) {
+			return fmt.Errorf("audit entry %d: expected seq %d, got %d", i, i, entry.Seq)
+		}
+		if entry.PrevHash != prevHash {
+			return fmt.Errorf("audit entry %d: prevHash %q does not match preceding entry's hash %q", i, entry.PrevHash, prevHash)
+		}
+
+		var snapshotManagers []string
+		if entry.Operation == AuditOperationStore {
+			if len(entry.AclSnapshot) == 0 {
+				return fmt.Errorf("audit entry %d: store entry is missing its acl snapshot", i)
+			}
+
+			acl, err := NewAclFromData(entry.AclSnapshot)
+			if err != nil {
+				return fmt.Errorf("audit entry %d: invalid acl snapshot: %w", i, err)
+			}
+
+			snapshotHash, err := acl.contentHash()
+			if err != nil {
+				return fmt.Errorf("audit entry %d: %w", i, err)
+			}
+			if snapshotHash != entry.NewAclHash {
+				return fmt.Errorf("audit entry %d: acl snapshot does not match newAclHash", i)
+			}
+
+			snapshotManagers = acl.Managers
+		}
+
+		if entry.Operation != AuditOperationReset {
+			validManagers := managers
+			if entry.Operation == AuditOperationStore {
+				validManagers = snapshotManagers
+			}
+
+			actorIsManager := false
+			for _, manager := range validManagers {
+				if manager == entry.ActorPubKey {
+					actorIsManager = true
+					break
+				}
+			}
+			if !actorIsManager {
+				return fmt.Errorf("audit entry %d: actor %s was not a manager at this point in history", i, entry.ActorPubKey)
+			}
+
+			actorKey, err := solana.PublicKeyFromBase58(entry.ActorPubKey)
+			if err != nil {
+				return fmt.Errorf("audit entry %d: invalid actor pubkey: %w", i, err)
+			}
+
+			signature, err := solana.NewSignatureFromBase58(entry.SignatureB58)
+			if err != nil {
+				return fmt.Errorf("audit entry %d: invalid signature encoding: %w", i, err)
+			}
+
+			if !signature.Verify(actorKey, entry.messageToSign()) {
+				return fmt.Errorf("audit entry %d: invalid signature", i)
+			}
+		}
+
+		switch entry.Operation {
+		case AuditOperationStore:
+			managers = snapshotManagers
+		case AuditOperationClear, AuditOperationReset:
+			managers = nil
+		}
+
+		entryHash, err := entry.hash()
+		if err != nil {
+			return fmt.Errorf("audit entry %d: %w", i, err)
+		}
+		prevHash = entryHash
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package hivemapper_hdc_acl
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/streamingfast/solana-go"
+)
+
+// CapabilityAclClear is the capability a BearerToken must carry to
+// authorize AclClearFromDevice, via a BearerClearAuthorization, in place of
+// a manager signature.
+const CapabilityAclClear = "acl:clear"
+
+// bearerMessagePrefix is prepended to every bearer token's canonical
+// message so a token signature can never be confused with a signature over
+// an unrelated message.
+const bearerMessagePrefix = "Hivemapper ACL bearer token v1"
+
+// BearerToken lets a manager delegate a scoped, time-bound set of
+// capabilities to another pubkey without sharing its key. Tokens are never
+// persisted to the device; they are presented and validated per request via
+// Acl.ValidateBearer.
+type BearerToken struct {
+	Issuer       solana.PublicKey `json:"issuer"`
+	Subject      solana.PublicKey `json:"subject"`
+	Capabilities []string         `json:"capabilities"`
+	NotBefore    time.Time        `json:"notBefore"`
+	NotAfter     time.Time        `json:"notAfter"`
+	Nonce        string           `json:"nonce"`
+	Signature    solana.Signature `json:"signature"`
+}
+
+func NewBearerToken(issuer, subject solana.PublicKey, capabilities []string, notBefore, notAfter time.Time, nonce string, signature solana.Signature) *BearerToken {
+	return &BearerToken{
+		Issuer:       issuer,
+		Subject:      subject,
+		Capabilities: capabilities,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		Nonce:        nonce,
+		Signature:    signature,
+	}
+}
+
+// MessageToSign returns the deterministic message the Issuer must sign to
+// authorize this token.
+func (b *BearerToken) MessageToSign() ([]byte, error) {
+	capabilities := append([]string(nil), b.Capabilities...)
+	sort.Strings(capabilities)
+
+	hashable := struct {
+		Issuer       string    `json:"issuer"`
+		Subject      string    `json:"subject"`
+		Capabilities []string  `json:"capabilities"`
+		NotBefore    time.Time `json:"notBefore"`
+		NotAfter     time.Time `json:"notAfter"`
+		Nonce        string    `json:"nonce"`
+	}{
+		Issuer:       b.Issuer.String(),
+		Subject:      b.Subject.String(),
+		Capabilities: capabilities,
+		NotBefore:    b.NotBefore,
+		NotAfter:     b.NotAfter,
+		Nonce:        b.Nonce,
+	}
+
+	data, err := json.Marshal(hashable)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling bearer token: %s", err)
+	}
+
+	h := md5.New()
+	io.WriteString(h, string(data))
+	hexHash := hex.EncodeToString(h.Sum(nil))
+
+	return []byte(fmt.Sprintf("%s. Hash: %s", bearerMessagePrefix, hexHash)), nil
+}
+
+func (b *BearerToken) hasCapability(action string) bool {
+	for _, capability := range b.Capabilities {
+		if capability == action {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerReplayCacheSize bounds the in-memory nonce-replay cache so it can't
+// grow without limit across the lifetime of a process.
+const bearerReplayCacheSize = 1024
+
+// bearerReplayCache is a small bounded, FIFO-evicted set used to reject a
+// bearer token presented twice for the same action within its validity
+// window.
+type bearerReplayCache struct {
+	mu    sync.Mutex
+	seen  map[string]struct{}
+	order []string
+}
+
+var defaultBearerReplayCache = &bearerReplayCache{seen: map[string]struct{}{}}
+
+func (c *bearerReplayCache) seenBefore(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.seen[key]; ok {
+		return true
+	}
+
+	c.seen[key] = struct{}{}
+	c.order = append(c.order, key)
+	if len(c.order) > bearerReplayCacheSize {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.seen, oldest)
+	}
+
+	return false
+}
+
+func bearerReplayKey(token *BearerToken, action string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", token.Issuer.String(), token.Subject.String(), token.Nonce, action)
+}
+
+// ValidateBearer reports whether token authorizes presenter to perform
+// action against a. It checks that the issuer is a current, non-revoked
+// manager, that token.Signature is the issuer's valid signature over
+// token.MessageToSign, that now falls within [NotBefore, NotAfter], that
+// action is among Capabilities, that presenter matches Subject and
+// presenterSig is its valid signature over requestPayload, and that this
+// exact token+action has not already been presented during its validity
+// window.
+func (a *Acl) ValidateBearer(token *BearerToken, presenter solana.PublicKey, presenterSig solana.Signature, action string, requestPayload []byte) error {
+	issuerIsManager := false
+	for _, manager := range a.Managers {
+		if manager == token.Issuer.String() {
+			issuerIsManager = true
+			break
+		}
+	}
+	if !issuerIsManager {
+		return fmt.Errorf("bearer token issuer is not a current manager")
+	}
+
+	if a.IsRevoked(token.Issuer) {
+		return fmt.Errorf("bearer token issuer has been revoked")
+	}
+
+	message, err := token.MessageToSign()
+	if err != nil {
+		return fmt.Errorf("building bearer token message: %w", err)
+	}
+	if !token.Signature.Verify(token.Issuer, message) {
+		return fmt.Errorf("invalid bearer token signature")
+	}
+
+	now := time.Now()
+	if now.Before(token.NotBefore) || now.After(token.NotAfter) {
+		return fmt.Errorf("bearer token is outside its validity window")
+	}
+
+	if !token.hasCapability(action) {
+		return fmt.Errorf("bearer token does not carry capability %q", action)
+	}
+
+	if presenter.String() != token.Subject.String() {
+		return fmt.Errorf("presenter does not match bearer token subject")
+	}
+
+	if !presenterSig.Verify(presenter, requestPayload) {
+		return fmt.Errorf("invalid presenter signature")
+	}
+
+	if defaultBearerReplayCache.seenBefore(bearerReplayKey(token, action)) {
+		return fmt.Errorf("bearer token already used for capability %q", action)
+	}
+
+	return nil
+}
+
+// BearerClearAuthorization is the bearer-token alternative to a direct
+// manager signatureB58, passed to AclClearFromDevice. Token must carry
+// CapabilityAclClear; Presenter/PresenterSig prove whoever is presenting
+// the token holds Token.Subject's key over RequestPayload.
+type BearerClearAuthorization struct {
+	Token          *BearerToken
+	Presenter      solana.PublicKey
+	PresenterSig   solana.Signature
+	RequestPayload []byte
+}
@@ -0,0 +1,100 @@
+package hivemapper_hdc_acl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/streamingfast/solana-go"
+)
+
+func TestValidateBearerExpired(t *testing.T) {
+	manager, subject, acl := bearerTestAcl(t)
+	token := bearerTestToken(t, manager, subject, []string{CapabilityAclClear}, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), "nonce-expired")
+
+	payload := []byte("clear-request")
+	presenterSig := subject.sign(t, payload)
+
+	if err := acl.ValidateBearer(token, subject.pubKey, presenterSig, CapabilityAclClear, payload); err == nil {
+		t.Fatalf("expected an expired bearer token to be rejected")
+	}
+}
+
+func TestValidateBearerWrongIssuer(t *testing.T) {
+	_, subject, acl := bearerTestAcl(t)
+	notAManager := newTestManager(t)
+	token := bearerTestToken(t, notAManager, subject, []string{CapabilityAclClear}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "nonce-wrong-issuer")
+
+	payload := []byte("clear-request")
+	presenterSig := subject.sign(t, payload)
+
+	if err := acl.ValidateBearer(token, subject.pubKey, presenterSig, CapabilityAclClear, payload); err == nil {
+		t.Fatalf("expected a token issued by a non-manager to be rejected")
+	}
+}
+
+func TestValidateBearerWrongSubject(t *testing.T) {
+	manager, subject, acl := bearerTestAcl(t)
+	impostor := newTestManager(t)
+	token := bearerTestToken(t, manager, subject, []string{CapabilityAclClear}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "nonce-wrong-subject")
+
+	payload := []byte("clear-request")
+	presenterSig := impostor.sign(t, payload)
+
+	if err := acl.ValidateBearer(token, impostor.pubKey, presenterSig, CapabilityAclClear, payload); err == nil {
+		t.Fatalf("expected a presenter that doesn't match the token subject to be rejected")
+	}
+}
+
+func TestValidateBearerMissingCapability(t *testing.T) {
+	manager, subject, acl := bearerTestAcl(t)
+	token := bearerTestToken(t, manager, subject, []string{CapabilityDashcamUpload}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "nonce-missing-capability")
+
+	payload := []byte("clear-request")
+	presenterSig := subject.sign(t, payload)
+
+	if err := acl.ValidateBearer(token, subject.pubKey, presenterSig, CapabilityAclClear, payload); err == nil {
+		t.Fatalf("expected a token without the requested capability to be rejected")
+	}
+}
+
+func TestValidateBearerReplay(t *testing.T) {
+	manager, subject, acl := bearerTestAcl(t)
+	token := bearerTestToken(t, manager, subject, []string{CapabilityAclClear}, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), "nonce-replay")
+
+	payload := []byte("clear-request")
+	presenterSig := subject.sign(t, payload)
+
+	if err := acl.ValidateBearer(token, subject.pubKey, presenterSig, CapabilityAclClear, payload); err != nil {
+		t.Fatalf("expected the first presentation to be accepted: %s", err)
+	}
+
+	if err := acl.ValidateBearer(token, subject.pubKey, presenterSig, CapabilityAclClear, payload); err == nil {
+		t.Fatalf("expected the same token+action+nonce to be rejected as a replay")
+	}
+}
+
+func bearerTestAcl(t *testing.T) (testManager, testManager, *Acl) {
+	t.Helper()
+
+	manager := newTestManager(t)
+	subject := newTestManager(t)
+
+	acl := &Acl{Version: "2", Managers: []string{manager.pubKeyB58}, FleetName: "fleet-a"}
+
+	return manager, subject, acl
+}
+
+func bearerTestToken(t *testing.T, issuer, subject testManager, capabilities []string, notBefore, notAfter time.Time, nonce string) *BearerToken {
+	t.Helper()
+
+	token := NewBearerToken(issuer.pubKey, subject.pubKey, capabilities, notBefore, notAfter, nonce, solana.Signature{})
+
+	message, err := token.MessageToSign()
+	if err != nil {
+		t.Fatalf("building bearer token message: %s", err)
+	}
+
+	token.Signature = issuer.sign(t, message)
+
+	return token
+}
@@ -0,0 +1,185 @@
+package hivemapper_hdc_acl
+
+import (
+	"sort"
+
+	"github.com/streamingfast/solana-go"
+)
+
+// Built-in policy and role names backing the legacy Managers/Drivers lists,
+// so existing ACLs keep their original authorization semantics once they
+// are evaluated through Authorize.
+const (
+	ManagerPolicyName = "manager"
+	DriverPolicyName  = "driver"
+
+	ManagerRoleName = "manager"
+	DriverRoleName  = "driver"
+)
+
+// Capability names known to this package. Callers are free to declare and
+// authorize against their own capability strings as well.
+const (
+	CapabilityAclModify     = "acl:modify"
+	CapabilityConfigWrite   = "config:write"
+	CapabilityDashcamUpload = "dashcam:upload"
+)
+
+// Policy is a named set of capability rules. A rule value of true is an
+// allow and false is a deny; a deny always overrides an allow found through
+// another policy granted to the same signer.
+type Policy struct {
+	Name  string          `json:"name"`
+	Rules map[string]bool `json:"rules"`
+}
+
+// Role is a named bundle of policies that can be granted to an Entry.
+type Role struct {
+	Name     string   `json:"name"`
+	Policies []string `json:"policies"`
+}
+
+// Entry binds a base58-encoded pubkey to the roles and/or policies it is
+// granted.
+type Entry struct {
+	PubKey   string   `json:"pubKey"`
+	Roles    []string `json:"roles,omitempty"`
+	Policies []string `json:"policies,omitempty"`
+}
+
+func builtinPolicies() []Policy {
+	return []Policy{
+		{
+			Name: ManagerPolicyName,
+			Rules: map[string]bool{
+				CapabilityAclModify:     true,
+				CapabilityConfigWrite:   true,
+				CapabilityDashcamUpload: true,
+			},
+		},
+		{
+			Name: DriverPolicyName,
+			Rules: map[string]bool{
+				CapabilityDashcamUpload: true,
+				CapabilityAclModify:     false,
+				CapabilityConfigWrite:   false,
+			},
+		},
+	}
+}
+
+func builtinRoles() []Role {
+	return []Role{
+		{Name: ManagerRoleName, Policies: []string{ManagerPolicyName}},
+		{Name: DriverRoleName, Policies: []string{DriverPolicyName}},
+	}
+}
+
+// policyByName looks up a policy declared on the Acl, falling back to the
+// built-in policies backing the legacy Managers/Drivers lists.
+func (a *Acl) policyByName(name string) (Policy, bool) {
+	for _, p := range a.Policies {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	for _, p := range builtinPolicies() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Policy{}, false
+}
+
+func (a *Acl) roleByName(name string) (Role, bool) {
+	for _, r := range a.Roles {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	for _, r := range builtinRoles() {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Role{}, false
+}
+
+// effectivePolicyNames resolves the full set of policy names granted to
+// pubKey, combining its Entry (if any) with the legacy Managers/Drivers
+// shims.
+func (a *Acl) effectivePolicyNames(pubKey string) []string {
+	var names []string
+
+	for _, entry := range a.Entries {
+		if entry.PubKey != pubKey {
+			continue
+		}
+		names = append(names, entry.Policies...)
+		for _, roleName := range entry.Roles {
+			if role, ok := a.roleByName(roleName); ok {
+				names = append(names, role.Policies...)
+			}
+		}
+	}
+
+	for _, manager := range a.Managers {
+		if manager == pubKey {
+			names = append(names, ManagerPolicyName)
+		}
+	}
+	for _, driver := range a.Drivers {
+		if driver == pubKey {
+			names = append(names, DriverPolicyName)
+		}
+	}
+
+	return names
+}
+
+// Authorize reports whether pubkey may perform capability, walking every
+// policy granted to it through its Entry, roles, and the legacy
+// Managers/Drivers shims. A deny found in any granted policy overrides an
+// allow found in another. A signer with no policy mentioning capability is
+// denied, and a revoked pubkey is always denied regardless of the policies
+// granted to it.
+func (a *Acl) Authorize(pubkey solana.PublicKey, capability string) bool {
+	if a.IsRevoked(pubkey) {
+		return false
+	}
+
+	pubKey := pubkey.String()
+
+	allowed := false
+	for _, name := range a.effectivePolicyNames(pubKey) {
+		policy, ok := a.policyByName(name)
+		if !ok {
+			continue
+		}
+		decision, ok := policy.Rules[capability]
+		if !ok {
+			continue
+		}
+		if !decision {
+			return false
+		}
+		allowed = true
+	}
+
+	return allowed
+}
+
+// sortedPolicyTables returns the Acl's policy/role/entry tables sorted by
+// name/pubkey so they hash deterministically in storeMessageToSign.
+func sortedPolicyTables(a *Acl) ([]Policy, []Role, []Entry) {
+	policies := append([]Policy(nil), a.Policies...)
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+
+	roles := append([]Role(nil), a.Roles...)
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	entries := append([]Entry(nil), a.Entries...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].PubKey < entries[j].PubKey })
+
+	return policies, roles, entries
+}
@@ -0,0 +1,83 @@
+package hivemapper_hdc_acl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuthorizeDenyPrecedence(t *testing.T) {
+	signer := newTestManager(t)
+
+	acl := &Acl{
+		Policies: []Policy{
+			{Name: "allow-upload", Rules: map[string]bool{CapabilityDashcamUpload: true}},
+			{Name: "deny-upload", Rules: map[string]bool{CapabilityDashcamUpload: false}},
+		},
+		Entries: []Entry{
+			{PubKey: signer.pubKeyB58, Policies: []string{"allow-upload", "deny-upload"}},
+		},
+	}
+
+	if acl.Authorize(signer.pubKey, CapabilityDashcamUpload) {
+		t.Fatalf("expected a deny in any granted policy to override an allow in another")
+	}
+}
+
+func TestAuthorizeUnknownCapability(t *testing.T) {
+	signer := newTestManager(t)
+
+	acl := &Acl{
+		Policies: []Policy{
+			{Name: "upload-only", Rules: map[string]bool{CapabilityDashcamUpload: true}},
+		},
+		Entries: []Entry{
+			{PubKey: signer.pubKeyB58, Policies: []string{"upload-only"}},
+		},
+	}
+
+	if acl.Authorize(signer.pubKey, CapabilityAclModify) {
+		t.Fatalf("expected a capability not mentioned by any granted policy to be denied")
+	}
+}
+
+func TestAuthorizeLegacyManagerDriverBackwardCompat(t *testing.T) {
+	manager := newTestManager(t)
+	driver := newTestManager(t)
+
+	// A v1 ACL file on disk: just managers/drivers, no policy tables.
+	data := []byte(`{"managers":["` + manager.pubKeyB58 + `"],"drivers":["` + driver.pubKeyB58 + `"]}`)
+
+	acl, err := NewAclFromData(data)
+	if err != nil {
+		t.Fatalf("loading v1 acl: %s", err)
+	}
+
+	if !acl.Authorize(manager.pubKey, CapabilityAclModify) {
+		t.Fatalf("expected the legacy manager shim to authorize acl:modify")
+	}
+	if acl.Authorize(driver.pubKey, CapabilityAclModify) {
+		t.Fatalf("expected the legacy driver shim to deny acl:modify")
+	}
+	if !acl.Authorize(driver.pubKey, CapabilityDashcamUpload) {
+		t.Fatalf("expected the legacy driver shim to authorize dashcam:upload")
+	}
+}
+
+func TestAuthorizeDeniesRevokedManager(t *testing.T) {
+	manager := newTestManager(t)
+
+	acl := &Acl{Managers: []string{manager.pubKeyB58}}
+
+	if !acl.Authorize(manager.pubKey, CapabilityAclModify) {
+		t.Fatalf("expected a non-revoked manager to be authorized")
+	}
+
+	acl.revocations = &RevocationList{
+		Generation: 1,
+		Revoked:    []RevokedKey{{PubKey: manager.pubKeyB58, RevokedAt: time.Now(), Reason: "lost device"}},
+	}
+
+	if acl.Authorize(manager.pubKey, CapabilityAclModify) {
+		t.Fatalf("expected a revoked manager to be denied every capability")
+	}
+}
@@ -0,0 +1,87 @@
+package hivemapper_hdc_acl
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// BootstrapResetFileName is the file an operator with filesystem access to
+// the device writes the ACL's content hash into, proving physical presence,
+// to recover a device whose manager keys are all lost. It mirrors Consul's
+// bootstrap-reset-index file.
+const BootstrapResetFileName = "acl-bootstrap-reset"
+
+// SignatureRequiredError is returned instead of the bare ErrSignatureRequired
+// when a caller attempts to clear an ACL without a manager signature. It
+// carries the content hash the ACL currently has, which an operator with
+// filesystem access to the device can write to BootstrapResetFileName to
+// authorize AclResetFromDevice.
+type SignatureRequiredError struct {
+	ExpectedHash string
+}
+
+func (e *SignatureRequiredError) Error() string {
+	return fmt.Sprintf("%s: write hash %q to %s on the device to reset without one", ErrSignatureRequired, e.ExpectedHash, BootstrapResetFileName)
+}
+
+func (e *SignatureRequiredError) Unwrap() error {
+	return ErrSignatureRequired
+}
+
+// AclResetFromDevice recovers a device whose ACL can no longer be cleared
+// because every manager private key has been lost. It succeeds only if
+// BootstrapResetFileName exists on the device and contains the exact
+// content hash of the ACL currently stored there, proving the caller has
+// filesystem access to the device rather than merely a copy of the ACL.
+// On success both the reset file and the ACL are removed.
+func AclResetFromDevice(aclFolder string) error {
+	resetFilePath := path.Join(aclFolder, BootstrapResetFileName)
+
+	resetFileData, err := os.ReadFile(resetFilePath)
+	if err != nil {
+		return fmt.Errorf("reading bootstrap reset file: %w", err)
+	}
+
+	acl, err := NewAclFromFile(aclFolder)
+	if err != nil {
+		return fmt.Errorf("unable to read acl: %w", err)
+	}
+
+	expectedHash, err := acl.contentHash()
+	if err != nil {
+		return fmt.Errorf("computing acl content hash: %w", err)
+	}
+
+	if strings.TrimSpace(string(resetFileData)) != expectedHash {
+		return fmt.Errorf("bootstrap reset file does not match the current acl content hash")
+	}
+
+	if err := os.Remove(resetFilePath); err != nil {
+		return fmt.Errorf("removing bootstrap reset file: %w", err)
+	}
+
+	if err := aclClearFromDevice(aclFolder); err != nil {
+		return fmt.Errorf("unable to clear acl: %w", err)
+	}
+
+	prevHash, seq, err := chainHead(aclFolder)
+	if err != nil {
+		return fmt.Errorf("reading audit log: %w", err)
+	}
+
+	entry := AuditEntry{
+		Seq:        seq,
+		PrevHash:   prevHash,
+		Timestamp:  time.Now(),
+		Operation:  AuditOperationReset,
+		NewAclHash: emptyAclHash,
+	}
+	if err := appendAuditEntry(aclFolder, entry); err != nil {
+		return fmt.Errorf("appending audit entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package hivemapper_hdc_acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAclResetFromDeviceWrongHashRejected(t *testing.T) {
+	dir := t.TempDir()
+	manager := newTestManager(t)
+	acl := &Acl{Version: "2", Managers: []string{manager.pubKeyB58}, FleetName: "fleet-a"}
+	storeAclForTest(t, dir, acl, manager)
+
+	resetFile := filepath.Join(dir, BootstrapResetFileName)
+	if err := os.WriteFile(resetFile, []byte("not-the-right-hash"), 0644); err != nil {
+		t.Fatalf("writing reset file: %s", err)
+	}
+
+	if err := AclResetFromDevice(dir); err == nil {
+		t.Fatalf("expected a mismatched reset file hash to be rejected")
+	}
+
+	if !AclExistOnDevice(dir) {
+		t.Fatalf("expected the acl to remain on device after a rejected reset")
+	}
+}
+
+func TestAclResetFromDeviceConsumesResetFile(t *testing.T) {
+	dir := t.TempDir()
+	manager := newTestManager(t)
+	acl := &Acl{Version: "2", Managers: []string{manager.pubKeyB58}, FleetName: "fleet-a"}
+	storeAclForTest(t, dir, acl, manager)
+
+	hash, err := acl.contentHash()
+	if err != nil {
+		t.Fatalf("computing content hash: %s", err)
+	}
+
+	resetFile := filepath.Join(dir, BootstrapResetFileName)
+	if err := os.WriteFile(resetFile, []byte(hash), 0644); err != nil {
+		t.Fatalf("writing reset file: %s", err)
+	}
+
+	if err := AclResetFromDevice(dir); err != nil {
+		t.Fatalf("reset: %s", err)
+	}
+
+	if AclExistOnDevice(dir) {
+		t.Fatalf("expected the acl to be removed after a successful reset")
+	}
+	if _, err := os.Stat(resetFile); !os.IsNotExist(err) {
+		t.Fatalf("expected the reset file to be consumed")
+	}
+}
+
+func TestResetCounterIncrementsAcrossStores(t *testing.T) {
+	dir := t.TempDir()
+	manager := newTestManager(t)
+	acl := &Acl{Version: "2", Managers: []string{manager.pubKeyB58}, FleetName: "fleet-a"}
+
+	storeAclForTest(t, dir, acl, manager)
+	if acl.ResetCounter != 1 {
+		t.Fatalf("expected ResetCounter to be 1 after the first store, got %d", acl.ResetCounter)
+	}
+
+	storeAclForTest(t, dir, acl, manager)
+	if acl.ResetCounter != 2 {
+		t.Fatalf("expected ResetCounter to be 2 after the second store, got %d", acl.ResetCounter)
+	}
+}
@@ -0,0 +1,332 @@
+package hivemapper_hdc_acl
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/streamingfast/solana-go"
+)
+
+const RevocationFileName = "revocations.data"
+
+// RevokedKey records that a manager or driver pubkey has been revoked and
+// why.
+type RevokedKey struct {
+	PubKey    string    `json:"pubKey"`
+	RevokedAt time.Time `json:"revokedAt"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// RevocationList is the signed, on-device list of revoked keys for a fleet.
+// It is stored separately from acl.data so a compromised key can be revoked
+// without rewriting the ACL itself.
+type RevocationList struct {
+	Version      string       `json:"version,omitempty"`
+	FleetName    string       `json:"fleetName,omitempty"`
+	Generation   uint64       `json:"generation"`
+	Revoked      []RevokedKey `json:"revoked"`
+	SignatureB58 string       `json:"signature"`
+}
+
+func NewRevocationListFromFile(sourceFolder string) (*RevocationList, error) {
+	revocationFile, err := os.Open(path.Join(sourceFolder, RevocationFileName))
+	if err != nil {
+		return nil, fmt.Errorf("opening revocation list file: %s", err)
+	}
+
+	data, err := io.ReadAll(revocationFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading revocation list file: %s", err)
+	}
+
+	if len(data) == 0 {
+		if err := clearCorruptedRevocationList(sourceFolder); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("revocation list file was empty and has been removed")
+	}
+
+	return NewRevocationListFromData(data)
+}
+
+func NewRevocationListFromData(data []byte) (*RevocationList, error) {
+	list := &RevocationList{}
+
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("unmarshalling revocation list data: %s", err)
+	}
+
+	return list, nil
+}
+
+func RevocationListExistOnDevice(sourceFolder string) bool {
+	if _, err := os.Stat(path.Join(sourceFolder, RevocationFileName)); errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	return true
+}
+
+func clearCorruptedRevocationList(sourceFolder string) error {
+	corrupted := path.Join(sourceFolder, RevocationFileName)
+	if _, err := os.Stat(corrupted); err == nil {
+		if err := os.Remove(corrupted); err != nil {
+			return fmt.Errorf("removing corrupted revocation list file: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// sortedRevokedKeys returns revoked sorted by pubkey so the list hashes
+// deterministically regardless of insertion order.
+func sortedRevokedKeys(revoked []RevokedKey) []RevokedKey {
+	sorted := append([]RevokedKey(nil), revoked...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].PubKey < sorted[j].PubKey })
+	return sorted
+}
+
+func (r *RevocationList) messageToSign() ([]byte, error) {
+	data, err := json.Marshal(sortedRevokedKeys(r.Revoked))
+	if err != nil {
+		return nil, fmt.Errorf("marshalling revocation list: %s", err)
+	}
+
+	h := md5.New()
+	io.WriteString(h, string(data))
+	hexHash := hex.EncodeToString(h.Sum(nil))
+
+	message := fmt.Sprintf("Revocation list gen=%d for fleet %s with %d entries. Hash: %s", r.Generation, r.FleetName, len(r.Revoked), hexHash)
+
+	return []byte(message), nil
+}
+
+// ValidateSignature reports whether the list's SignatureB58 is a valid
+// manager signature over its canonical message, checking against managers
+// (typically Acl.Managers).
+func (r *RevocationList) ValidateSignature(managers []string) bool {
+	signature, err := solana.NewSignatureFromBase58(r.SignatureB58)
+	if err != nil {
+		return false
+	}
+
+	data, err := r.messageToSign()
+	if err != nil {
+		return false
+	}
+
+	for _, managerAddress := range managers {
+		pubKey, err := solana.PublicKeyFromBase58(managerAddress)
+		if err != nil {
+			return false
+		}
+		if signature.Verify(pubKey, data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RevocationListStore validates list against managers and writes it to
+// destinationFolder, replacing any revocation list already there. list is
+// rejected unless its Generation strictly advances the Generation of
+// whatever revocation list already exists in destinationFolder (treating no
+// existing list as generation 0), the same replay protection
+// ApplyRevocationDelta applies to deltas: without it, a list that was
+// validly signed before a key was revoked could be replayed later to
+// silently un-revoke it.
+func RevocationListStore(destinationFolder string, managers []string, list *RevocationList) error {
+	if !list.ValidateSignature(managers) {
+		return fmt.Errorf("invalid signature")
+	}
+
+	var currentGeneration uint64
+	if RevocationListExistOnDevice(destinationFolder) {
+		current, err := NewRevocationListFromFile(destinationFolder)
+		if err != nil {
+			return fmt.Errorf("reading current revocation list: %w", err)
+		}
+		currentGeneration = current.Generation
+	}
+	if list.Generation <= currentGeneration {
+		return fmt.Errorf("revocation list generation %d does not advance current generation %d", list.Generation, currentGeneration)
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("marshalling revocation list: %s", err)
+	}
+
+	if err := os.MkdirAll(destinationFolder, os.ModePerm); err != nil {
+		return fmt.Errorf("creating destination folder: %w", err)
+	}
+
+	revocationFile := path.Join(destinationFolder, RevocationFileName)
+
+	f, err := os.OpenFile(revocationFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("opening file %w", err)
+	}
+
+	_, werr := f.Write(data)
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("synching file %w", err)
+	}
+
+	if cerr := f.Close(); cerr != nil && werr == nil {
+		return fmt.Errorf("closing file %w", cerr)
+	}
+
+	if werr != nil {
+		return fmt.Errorf("writing file %w", werr)
+	}
+
+	return nil
+}
+
+// RevocationDelta carries only the additions/removals needed to move a
+// RevocationList from BaseGeneration to Generation, so a full list doesn't
+// need to be resigned and redistributed for every revocation.
+type RevocationDelta struct {
+	Version        string       `json:"version,omitempty"`
+	FleetName      string       `json:"fleetName,omitempty"`
+	BaseGeneration uint64       `json:"baseGeneration"`
+	Generation     uint64       `json:"generation"`
+	Added          []RevokedKey `json:"added,omitempty"`
+	Removed        []string     `json:"removed,omitempty"`
+	SignatureB58   string       `json:"signature"`
+}
+
+func (d *RevocationDelta) messageToSign() ([]byte, error) {
+	hashableDelta := struct {
+		Added   []RevokedKey `json:"added,omitempty"`
+		Removed []string     `json:"removed,omitempty"`
+	}{
+		Added:   sortedRevokedKeys(d.Added),
+		Removed: append([]string(nil), d.Removed...),
+	}
+	sort.Strings(hashableDelta.Removed)
+
+	data, err := json.Marshal(hashableDelta)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling revocation delta: %s", err)
+	}
+
+	h := md5.New()
+	io.WriteString(h, string(data))
+	hexHash := hex.EncodeToString(h.Sum(nil))
+
+	message := fmt.Sprintf("Revocation delta gen=%d base=%d for fleet %s with %d added, %d removed. Hash: %s",
+		d.Generation, d.BaseGeneration, d.FleetName, len(d.Added), len(d.Removed), hexHash)
+
+	return []byte(message), nil
+}
+
+// ValidateSignature reports whether the delta's SignatureB58 is a valid
+// manager signature over its canonical message.
+func (d *RevocationDelta) ValidateSignature(managers []string) bool {
+	signature, err := solana.NewSignatureFromBase58(d.SignatureB58)
+	if err != nil {
+		return false
+	}
+
+	data, err := d.messageToSign()
+	if err != nil {
+		return false
+	}
+
+	for _, managerAddress := range managers {
+		pubKey, err := solana.PublicKeyFromBase58(managerAddress)
+		if err != nil {
+			return false
+		}
+		if signature.Verify(pubKey, data) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ApplyRevocationDelta validates delta against managers and returns the
+// RevocationList obtained by applying it on top of base. delta is rejected
+// if its BaseGeneration does not match base's current Generation, which
+// covers both a delta built against a now-stale generation and one whose
+// base is unknown to this list. Applying the same delta twice in a row is a
+// no-op the second time since the generation check fails once it has
+// already advanced.
+func ApplyRevocationDelta(base *RevocationList, delta *RevocationDelta, managers []string) (*RevocationList, error) {
+	if !delta.ValidateSignature(managers) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+
+	if delta.BaseGeneration != base.Generation {
+		return nil, fmt.Errorf("delta base generation %d does not match current generation %d", delta.BaseGeneration, base.Generation)
+	}
+
+	revoked := map[string]RevokedKey{}
+	for _, k := range base.Revoked {
+		revoked[k.PubKey] = k
+	}
+	for _, removed := range delta.Removed {
+		delete(revoked, removed)
+	}
+	for _, added := range delta.Added {
+		revoked[added.PubKey] = added
+	}
+
+	merged := make([]RevokedKey, 0, len(revoked))
+	for _, k := range revoked {
+		merged = append(merged, k)
+	}
+
+	return &RevocationList{
+		Version:    base.Version,
+		FleetName:  base.FleetName,
+		Generation: delta.Generation,
+		Revoked:    sortedRevokedKeys(merged),
+	}, nil
+}
+
+// IsRevoked reports whether pubkey appears in the revocation list currently
+// loaded for a. It returns false when no revocation list has been loaded,
+// so ACLs predating this subsystem keep working unchanged.
+func (a *Acl) IsRevoked(pubkey solana.PublicKey) bool {
+	if a.revocations == nil {
+		return false
+	}
+
+	pubKeyStr := pubkey.String()
+	for _, revoked := range a.revocations.Revoked {
+		if revoked.PubKey == pubKeyStr {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadRevocations loads the revocation list from aclFolder, if any, so
+// subsequent validateSignature calls reject revoked managers. A missing or
+// unreadable revocation list is not an error: revocations are optional.
+func (a *Acl) loadRevocations(aclFolder string) {
+	if !RevocationListExistOnDevice(aclFolder) {
+		return
+	}
+
+	list, err := NewRevocationListFromFile(aclFolder)
+	if err != nil {
+		return
+	}
+
+	a.revocations = list
+}
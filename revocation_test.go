@@ -0,0 +1,117 @@
+package hivemapper_hdc_acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRevokedManagerCannotSignClear(t *testing.T) {
+	manager := newTestManager(t)
+
+	acl := &Acl{
+		Version:   "2",
+		Managers:  []string{manager.pubKeyB58},
+		FleetName: "fleet-a",
+	}
+
+	message, err := acl.clearMessageToSign()
+	if err != nil {
+		t.Fatalf("building clear message: %s", err)
+	}
+	signature := manager.sign(t, message)
+
+	if !acl.ValidateClearSignature(signature) {
+		t.Fatalf("expected a non-revoked manager's signature to validate")
+	}
+
+	acl.revocations = &RevocationList{
+		FleetName:  "fleet-a",
+		Generation: 1,
+		Revoked:    []RevokedKey{{PubKey: manager.pubKeyB58, RevokedAt: time.Now(), Reason: "lost device"}},
+	}
+
+	if acl.ValidateClearSignature(signature) {
+		t.Fatalf("expected a revoked manager's signature to be rejected")
+	}
+}
+
+func TestApplyRevocationDeltaRejectsStaleReplay(t *testing.T) {
+	manager := newTestManager(t)
+	managers := []string{manager.pubKeyB58}
+
+	base := &RevocationList{FleetName: "fleet-a", Generation: 0}
+
+	delta := &RevocationDelta{
+		FleetName:      "fleet-a",
+		BaseGeneration: 0,
+		Generation:     1,
+		Added:          []RevokedKey{{PubKey: "revoked-key-1", RevokedAt: time.Now()}},
+	}
+	deltaMessage, err := delta.messageToSign()
+	if err != nil {
+		t.Fatalf("building delta message: %s", err)
+	}
+	delta.SignatureB58 = manager.sign(t, deltaMessage).String()
+
+	updated, err := ApplyRevocationDelta(base, delta, managers)
+	if err != nil {
+		t.Fatalf("applying delta: %s", err)
+	}
+	if updated.Generation != 1 || len(updated.Revoked) != 1 {
+		t.Fatalf("unexpected list after applying delta: %+v", updated)
+	}
+
+	if _, err := ApplyRevocationDelta(updated, delta, managers); err == nil {
+		t.Fatalf("expected re-applying the same delta against its own result to be rejected as a stale base generation")
+	}
+}
+
+func TestNewRevocationListFromFileCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	revocationFile := filepath.Join(dir, RevocationFileName)
+	if err := os.WriteFile(revocationFile, nil, 0644); err != nil {
+		t.Fatalf("writing empty revocation file: %s", err)
+	}
+
+	if _, err := NewRevocationListFromFile(dir); err == nil {
+		t.Fatalf("expected an error for a corrupted revocation list, not a panic")
+	}
+
+	if _, err := os.Stat(revocationFile); !os.IsNotExist(err) {
+		t.Fatalf("expected the corrupted revocation file to have been removed")
+	}
+}
+
+func TestRevocationListStoreRejectsStaleGenerationReplay(t *testing.T) {
+	dir := t.TempDir()
+	manager := newTestManager(t)
+	managers := []string{manager.pubKeyB58}
+
+	stale := &RevocationList{FleetName: "fleet-a", Generation: 1, Revoked: []RevokedKey{{PubKey: "revoked-key-1", RevokedAt: time.Now()}}}
+	staleMessage, err := stale.messageToSign()
+	if err != nil {
+		t.Fatalf("building stale list message: %s", err)
+	}
+	stale.SignatureB58 = manager.sign(t, staleMessage).String()
+
+	if err := RevocationListStore(dir, managers, stale); err != nil {
+		t.Fatalf("storing initial list: %s", err)
+	}
+
+	current := &RevocationList{FleetName: "fleet-a", Generation: 2}
+	currentMessage, err := current.messageToSign()
+	if err != nil {
+		t.Fatalf("building current list message: %s", err)
+	}
+	current.SignatureB58 = manager.sign(t, currentMessage).String()
+
+	if err := RevocationListStore(dir, managers, current); err != nil {
+		t.Fatalf("storing current list: %s", err)
+	}
+
+	if err := RevocationListStore(dir, managers, stale); err == nil {
+		t.Fatalf("expected replaying a stale, validly-signed revocation list to be rejected")
+	}
+}